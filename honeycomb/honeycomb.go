@@ -0,0 +1,498 @@
+// Package honeycomb implements an OpenTelemetry exporter that sends spans to
+// Honeycomb (https://www.honeycomb.io) via libhoney.
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	apitrace "go.opentelemetry.io/otel/api/trace"
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// serviceNameKey is the resource attribute that Config.ServiceName is sugar
+// for.
+const serviceNameKey = core.Key("service.name")
+
+// Config carries the user-visible settings for the exporter.
+type Config struct {
+	// ApiKey is the Honeycomb write key used to authenticate events.
+	ApiKey string
+	// Dataset is the Honeycomb dataset that events are sent to.
+	Dataset string
+	// ServiceName, if set, is sugar for a "service.name" attribute on the
+	// exporter's default Resource (see WithResource).
+	ServiceName string
+	// InlineLinks, if true, additionally attaches each link recorded on a
+	// span to that span's own event, as a "trace.links" array field. This
+	// is in addition to, not instead of, the sibling link events the
+	// exporter always sends, and is meant for consumers that prefer a
+	// single-event join over following those sibling events.
+	InlineLinks bool
+	// AttributeProcessors is applied, in order, to every attribute on a
+	// span, message event, and link before it is added to the
+	// corresponding libhoney event. Use it to redact sensitive values,
+	// rename keys, or drop attributes outright; see RedactAttributes,
+	// RewriteAttributePrefix, and DropOversizedAttributes for built-in
+	// processors covering common cases.
+	//
+	// There's no corresponding processor for preserving an attribute's
+	// numeric type: valueToInterface does that unconditionally for every
+	// attribute, whether or not AttributeProcessors is set, since it's a
+	// correctness fix rather than an opt-in behavior.
+	AttributeProcessors []AttributeProcessor
+	// Classic selects which Honeycomb dataset-routing model the exporter
+	// targets. If true, ApiKey is a classic write key scoped to the whole
+	// account, and Dataset names the destination dataset directly: it's
+	// required, and every event is routed to it. If false (the default),
+	// ApiKey is an environment-scoped write key, and events are sent to
+	// Dataset if set, or to defaultEnvironmentDataset otherwise, never to
+	// a dataset derived from the span; Dataset becomes optional, and service.name is left
+	// to act purely as a field that distinguishes services within the
+	// environment, not as a routing key. Classic lets a caller switch
+	// between the two models without rewriting its instrumentation.
+	Classic bool
+}
+
+// defaultEnvironmentDataset is the Dataset used outside classic mode
+// (Config.Classic false) when the caller doesn't set one. Honeycomb
+// routes events in the environment-scoped model by the write key's
+// environment, not by dataset, so every service can share this one
+// dataset; it only needs a concrete value because libhoney's transport
+// requires a non-empty Dataset.
+const defaultEnvironmentDataset = "default"
+
+// AttributeProcessor transforms or filters a single span attribute before
+// it is added to a libhoney event. It runs as part of the pipeline given
+// by Config.AttributeProcessors, with each processor seeing the key and
+// value the previous one produced. Returning drop true removes the
+// attribute entirely, and no later processor in the pipeline sees it.
+type AttributeProcessor func(key string, value interface{}) (newKey string, newValue interface{}, drop bool)
+
+// RedactAttributes returns an AttributeProcessor that replaces the value
+// of any attribute whose key matches pattern with replacement, without
+// altering the key or dropping the attribute. Use it to keep PII-bearing
+// fields out of Honeycomb while still recording that they were present.
+func RedactAttributes(pattern *regexp.Regexp, replacement string) AttributeProcessor {
+	return func(key string, value interface{}) (string, interface{}, bool) {
+		if pattern.MatchString(key) {
+			return key, replacement, false
+		}
+		return key, value, false
+	}
+}
+
+// RewriteAttributePrefix returns an AttributeProcessor that replaces a
+// leading oldPrefix on an attribute's key with newPrefix, leaving keys
+// that don't carry oldPrefix untouched.
+func RewriteAttributePrefix(oldPrefix, newPrefix string) AttributeProcessor {
+	return func(key string, value interface{}) (string, interface{}, bool) {
+		if strings.HasPrefix(key, oldPrefix) {
+			key = newPrefix + strings.TrimPrefix(key, oldPrefix)
+		}
+		return key, value, false
+	}
+}
+
+// DropOversizedAttributes returns an AttributeProcessor that drops any
+// attribute whose value, once formatted as a string, exceeds maxLen
+// bytes.
+func DropOversizedAttributes(maxLen int) AttributeProcessor {
+	return func(key string, value interface{}) (string, interface{}, bool) {
+		if len(fmt.Sprint(value)) > maxLen {
+			return key, value, true
+		}
+		return key, value, false
+	}
+}
+
+// Option configures an Exporter at construction time.
+type Option func(*Exporter)
+
+// WithResource sets the default Resource that the exporter attaches to
+// every event. A span carrying its own Resource (SpanData.Resource)
+// overrides this default entirely for that span, rather than being merged
+// with it.
+func WithResource(r *resource.Resource) Option {
+	return func(e *Exporter) {
+		e.Resource = r
+	}
+}
+
+// BatchConfig tunes the batching and sampling NewBatchExporter applies
+// before spans reach Honeycomb.
+type BatchConfig struct {
+	// MaxQueueSize bounds the number of spans buffered for export before
+	// new spans are dropped. Zero selects the SDK's default.
+	MaxQueueSize int
+	// MaxExportBatchSize bounds how many spans are coalesced into a
+	// single export pass. Zero selects the SDK's default.
+	MaxExportBatchSize int
+	// ScheduledDelay is the delay between two consecutive export passes.
+	// Zero selects the SDK's default.
+	ScheduledDelay time.Duration
+	// ExportTimeout bounds how long a single export pass may run. Zero
+	// means no additional deadline is imposed beyond the caller's
+	// context.
+	ExportTimeout time.Duration
+
+	// Sampler makes a deterministic keep/drop decision for a span, keyed
+	// on its hex-encoded trace ID, and reports the sample rate that
+	// decision represents. Spans it drops are never sent to Honeycomb;
+	// spans it keeps are stamped with the returned rate so Honeycomb can
+	// extrapolate accordingly. A nil Sampler keeps every span at a
+	// sample rate of 1.
+	Sampler func(traceID string) (keep bool, rate uint)
+}
+
+// Exporter is an implementation of the OpenTelemetry SDK's SpanSyncer and
+// SpanBatcher interfaces that sends spans to Honeycomb.
+type Exporter struct {
+	// Builder is used to construct every libhoney event sent by the
+	// exporter. It is exported so that callers (and tests) can customize
+	// or replace it, for example to point at a different libhoney Output.
+	Builder *libhoney.Builder
+
+	// Resource describes the entity producing spans when a given span
+	// does not carry its own Resource.
+	Resource *resource.Resource
+
+	// batch holds the settings NewBatchExporter was given. It is zero
+	// for an Exporter constructed with NewExporter.
+	batch BatchConfig
+
+	// inlineLinks mirrors Config.InlineLinks.
+	inlineLinks bool
+
+	// attributeProcessors mirrors Config.AttributeProcessors.
+	attributeProcessors []AttributeProcessor
+}
+
+// NewExporter configures libhoney and returns an Exporter that sends spans
+// to the Honeycomb dataset described by config.
+func NewExporter(config Config, opts ...Option) (*Exporter, error) {
+	if config.ApiKey == "" {
+		return nil, errors.New("honeycomb: Config.ApiKey is required")
+	}
+	if config.Classic {
+		// Classic mode's write key maps directly onto Dataset, so it must
+		// be given explicitly.
+		if config.Dataset == "" {
+			return nil, errors.New("honeycomb: Config.Dataset is required in classic mode")
+		}
+	} else if config.Dataset == "" {
+		// Outside classic mode, events route by the write key's
+		// environment rather than by Dataset, so every service can share
+		// defaultEnvironmentDataset; service.name remains just a field,
+		// never standing in for Dataset.
+		config.Dataset = defaultEnvironmentDataset
+	}
+
+	if err := libhoney.Init(libhoney.Config{
+		WriteKey: config.ApiKey,
+		Dataset:  config.Dataset,
+	}); err != nil {
+		return nil, err
+	}
+
+	exporter := &Exporter{
+		Builder:             libhoney.NewBuilder(),
+		inlineLinks:         config.InlineLinks,
+		attributeProcessors: config.AttributeProcessors,
+	}
+	for _, opt := range opts {
+		opt(exporter)
+	}
+	if config.ServiceName != "" {
+		// ServiceName never overrides a service.name attribute a caller
+		// already set via WithResource.
+		exporter.Resource = resource.Merge(exporter.Resource, resource.New(key.String(string(serviceNameKey), config.ServiceName)))
+	}
+
+	return exporter, nil
+}
+
+// NewBatchExporter is like NewExporter, but returns an Exporter that also
+// implements the SDK's export.SpanBatcher contract, so it can be
+// registered with a Provider via sdktrace.WithBatcher(exporter,
+// exporter.BatchSpanProcessorOptions()...) to coalesce spans into
+// libhoney events on a background goroutine instead of sending each span
+// synchronously.
+func NewBatchExporter(config Config, batchConfig BatchConfig, opts ...Option) (*Exporter, error) {
+	exporter, err := NewExporter(config, opts...)
+	if err != nil {
+		return nil, err
+	}
+	exporter.batch = batchConfig
+	return exporter, nil
+}
+
+// BatchSpanProcessorOptions translates the BatchConfig given to
+// NewBatchExporter into options for sdktrace.NewBatchSpanProcessor.
+func (e *Exporter) BatchSpanProcessorOptions() []sdktrace.BatchSpanProcessorOption {
+	var opts []sdktrace.BatchSpanProcessorOption
+	if e.batch.MaxQueueSize > 0 {
+		opts = append(opts, sdktrace.WithMaxQueueSize(e.batch.MaxQueueSize))
+	}
+	if e.batch.MaxExportBatchSize > 0 {
+		opts = append(opts, sdktrace.WithMaxExportBatchSize(e.batch.MaxExportBatchSize))
+	}
+	if e.batch.ScheduledDelay > 0 {
+		opts = append(opts, sdktrace.WithScheduleDelayMillis(e.batch.ScheduledDelay))
+	}
+	return opts
+}
+
+// Span is the Honeycomb representation of an OpenTelemetry span, shaped so
+// that libhoney can flatten it directly onto an event.
+type Span struct {
+	TraceID       string     `json:"trace.trace_id"`
+	ID            string     `json:"trace.span_id"`
+	Name          string     `json:"name"`
+	Kind          string     `json:"span.kind,omitempty"`
+	DurationMilli float64    `json:"duration_ms"`
+	StatusCode    int32      `json:"status.code"`
+	StatusMessage string     `json:"status.message,omitempty"`
+	Error         bool       `json:"error,omitempty"`
+	Links         []SpanLink `json:"trace.links,omitempty"`
+}
+
+// SpanLink is the Honeycomb representation of a link recorded on a span,
+// used when Config.InlineLinks attaches links directly onto the parent
+// span's own event rather than only as sibling events.
+type SpanLink struct {
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// getHoneycombTraceID converts the hex-encoded trace ID OpenTelemetry uses
+// internally into the dashed UUID form Honeycomb expects.
+func getHoneycombTraceID(hexTraceID string) string {
+	if len(hexTraceID) != 32 {
+		return hexTraceID
+	}
+	return hexTraceID[0:8] + "-" + hexTraceID[8:12] + "-" + hexTraceID[12:16] + "-" + hexTraceID[16:20] + "-" + hexTraceID[20:32]
+}
+
+// honeycombSpan translates a completed OpenTelemetry span into its
+// Honeycomb event representation. Links are always populated here; callers
+// that haven't enabled Config.InlineLinks clear them before sending.
+func honeycombSpan(data *exporttrace.SpanData) *Span {
+	sp := &Span{
+		TraceID:       getHoneycombTraceID(data.SpanContext.TraceIDString()),
+		ID:            data.SpanContext.SpanIDString(),
+		Name:          data.Name,
+		DurationMilli: float64(data.EndTime.Sub(data.StartTime).Nanoseconds()) / 1e6,
+		StatusCode:    int32(data.StatusCode),
+		StatusMessage: data.StatusMessage,
+		Error:         data.StatusCode != codes.OK,
+	}
+	if data.SpanKind != apitrace.SpanKindUnspecified {
+		sp.Kind = data.SpanKind.String()
+	}
+	if len(data.Links) > 0 {
+		sp.Links = honeycombLinks(data.Links)
+	}
+	return sp
+}
+
+// honeycombLinks translates a span's links into their inline Honeycomb
+// representation.
+func honeycombLinks(links []apitrace.Link) []SpanLink {
+	out := make([]SpanLink, len(links))
+	for i, link := range links {
+		out[i] = SpanLink{
+			TraceID: getHoneycombTraceID(link.SpanContext.TraceIDString()),
+			SpanID:  link.SpanContext.SpanIDString(),
+		}
+		if len(link.Attributes) > 0 {
+			out[i].Attributes = attributesToMap(link.Attributes)
+		}
+	}
+	return out
+}
+
+// attributesToMap converts attributes into a map keyed by attribute name,
+// preserving each value's native type.
+func attributesToMap(attrs []core.KeyValue) map[string]interface{} {
+	m := make(map[string]interface{}, len(attrs))
+	for _, kv := range attrs {
+		m[string(kv.Key)] = valueToInterface(kv.Value)
+	}
+	return m
+}
+
+// resourceFor returns the Resource that should be attached to events for
+// data: its own Resource if it carries one, completely overriding the
+// exporter's default, or the exporter's default Resource otherwise.
+func (e *Exporter) resourceFor(data *exporttrace.SpanData) *resource.Resource {
+	if data.Resource != nil {
+		return data.Resource
+	}
+	return e.Resource
+}
+
+// addResource stamps res's attributes onto ev, plus the legacy
+// "service_name" field derived from its "service.name" attribute.
+func addResource(ev *libhoney.Event, res *resource.Resource) {
+	if res == nil {
+		return
+	}
+	for _, kv := range res.Attributes() {
+		ev.AddField(string(kv.Key), valueToInterface(kv.Value))
+		if kv.Key == serviceNameKey {
+			ev.AddField("service_name", kv.Value.AsString())
+		}
+	}
+}
+
+// valueToInterface converts an attribute's core.Value into the native Go
+// type libhoney should store it as, preserving numeric and boolean types
+// rather than stringifying them.
+func valueToInterface(v core.Value) interface{} {
+	switch v.Type() {
+	case core.BOOL:
+		return v.AsBool()
+	case core.INT32:
+		return v.AsInt32()
+	case core.INT64:
+		return v.AsInt64()
+	case core.UINT32:
+		return v.AsUint32()
+	case core.UINT64:
+		return v.AsUint64()
+	case core.FLOAT32:
+		return v.AsFloat32()
+	case core.FLOAT64:
+		return v.AsFloat64()
+	case core.STRING:
+		return v.AsString()
+	default:
+		return v.Emit()
+	}
+}
+
+// addAttributes stamps each attribute onto ev as its own field, preserving
+// the attribute's native type, after running it through the exporter's
+// AttributeProcessors pipeline. It is used for span, message event, and
+// link attributes alike.
+func (e *Exporter) addAttributes(ev *libhoney.Event, attrs []core.KeyValue) {
+	for _, kv := range attrs {
+		key, value := string(kv.Key), valueToInterface(kv.Value)
+		drop := false
+		for _, proc := range e.attributeProcessors {
+			key, value, drop = proc(key, value)
+			if drop {
+				break
+			}
+		}
+		if drop {
+			continue
+		}
+		ev.AddField(key, value)
+	}
+}
+
+// ExportSpan sends data to Honeycomb as one event per message event or
+// link recorded on the span, followed by one event for the span itself.
+func (e *Exporter) ExportSpan(_ context.Context, data *exporttrace.SpanData) {
+	e.exportSpan(data, 1)
+}
+
+// exportSpan is the shared implementation behind ExportSpan and
+// ExportSpans. rate is stamped onto every emitted event as its libhoney
+// SampleRate, so Honeycomb can extrapolate accordingly.
+func (e *Exporter) exportSpan(data *exporttrace.SpanData, rate uint) {
+	traceID := getHoneycombTraceID(data.SpanContext.TraceIDString())
+	spanID := data.SpanContext.SpanIDString()
+	res := e.resourceFor(data)
+
+	for _, link := range data.Links {
+		ev := e.Builder.NewEvent()
+		ev.SampleRate = rate
+		ev.AddField("trace.trace_id", traceID)
+		ev.AddField("trace.parent_id", spanID)
+		ev.AddField("trace.link.trace_id", getHoneycombTraceID(link.SpanContext.TraceIDString()))
+		ev.AddField("trace.link.span_id", link.SpanContext.SpanIDString())
+		ev.AddField("meta.span_type", "link")
+		addResource(ev, res)
+		e.addAttributes(ev, link.Attributes)
+		ev.SendPresampled()
+	}
+
+	for _, me := range data.MessageEvents {
+		ev := e.Builder.NewEvent()
+		ev.SampleRate = rate
+		ev.AddField("name", me.Name)
+		ev.AddField("trace.trace_id", traceID)
+		ev.AddField("trace.parent_id", spanID)
+		ev.AddField("meta.span_type", "span_event")
+		addResource(ev, res)
+		e.addAttributes(ev, me.Attributes)
+		ev.SendPresampled()
+	}
+
+	ev := e.Builder.NewEvent()
+	ev.SampleRate = rate
+	sp := honeycombSpan(data)
+	if !e.inlineLinks {
+		sp.Links = nil
+	}
+	for i := range sp.Links {
+		if sp.Links[i].Attributes == nil {
+			continue
+		}
+		attrs := make(map[string]interface{})
+		for _, kv := range data.Links[i].Attributes {
+			key, value, drop := string(kv.Key), valueToInterface(kv.Value), false
+			for j := 0; j < len(e.attributeProcessors) && !drop; j++ {
+				key, value, drop = e.attributeProcessors[j](key, value)
+			}
+			if !drop {
+				attrs[key] = value
+			}
+		}
+		sp.Links[i].Attributes = attrs
+	}
+	ev.Add(sp)
+	addResource(ev, res)
+	e.addAttributes(ev, data.Attributes)
+	ev.SendPresampled()
+}
+
+// ExportSpans sends a batch of spans to Honeycomb, applying the Sampler
+// from the BatchConfig given to NewBatchExporter to each span in turn.
+// Spans the Sampler drops are never sent.
+func (e *Exporter) ExportSpans(ctx context.Context, spans []*exporttrace.SpanData) {
+	if e.batch.ExportTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.batch.ExportTimeout)
+		defer cancel()
+	}
+
+	for _, data := range spans {
+		if ctx.Err() != nil {
+			return
+		}
+
+		keep, rate := true, uint(1)
+		if e.batch.Sampler != nil {
+			keep, rate = e.batch.Sampler(data.SpanContext.TraceIDString())
+		}
+		if !keep {
+			continue
+		}
+		e.exportSpan(data, rate)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"github.com/google/uuid"
 	"reflect"
+	"regexp"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	libhoney "github.com/honeycombio/libhoney-go"
 	apitrace "go.opentelemetry.io/otel/api/trace"
 	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
@@ -78,10 +80,10 @@ func TestExport(t *testing.T) {
 					TraceID: traceID,
 					SpanID:  spanID,
 				},
-				Name:      "/baz",
-				StartTime: now,
-				EndTime:   now,
-				Status:    codes.OK,
+				Name:       "/baz",
+				StartTime:  now,
+				EndTime:    now,
+				StatusCode: codes.OK,
 			},
 			want: &Span{
 				TraceID:       expectedTraceID,
@@ -98,19 +100,48 @@ func TestExport(t *testing.T) {
 					TraceID: traceID,
 					SpanID:  spanID,
 				},
-				Name:      "/bazError",
-				StartTime: now,
-				EndTime:   now,
-				Status:    codes.PermissionDenied,
+				Name:       "/bazError",
+				StartTime:  now,
+				EndTime:    now,
+				StatusCode: codes.PermissionDenied,
 			},
 			want: &Span{
 				TraceID:       expectedTraceID,
 				ID:            expectedSpanID,
 				Name:          "/bazError",
 				DurationMilli: 0,
+				StatusCode:    int32(codes.PermissionDenied),
 				Error:         true,
 			},
 		},
+		{
+			name: "server span with status message and links",
+			data: &exporttrace.SpanData{
+				SpanContext:   core.SpanContext{TraceID: traceID, SpanID: spanID},
+				Name:          "/qux",
+				StartTime:     now,
+				EndTime:       now,
+				SpanKind:      apitrace.SpanKindServer,
+				StatusCode:    codes.NotFound,
+				StatusMessage: "no such resource",
+				Links: []apitrace.Link{
+					{SpanContext: core.SpanContext{TraceID: traceID, SpanID: spanID}},
+				},
+			},
+			want: &Span{
+				TraceID:       expectedTraceID,
+				ID:            expectedSpanID,
+				Name:          "/qux",
+				Kind:          "server",
+				DurationMilli: 0,
+				StatusCode:    int32(codes.NotFound),
+				StatusMessage: "no such resource",
+				Error:         true,
+				Links: []SpanLink{
+					{TraceID: expectedTraceID, SpanID: expectedSpanID},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		got := honeycombSpan(tt.data)
@@ -174,7 +205,7 @@ func TestHoneycombOutput(t *testing.T) {
 	assert.Equal(expectedSpanID, spanID)
 
 	name := mainEventFields["name"]
-	assert.Equal("honeycomb/test/myTestSpan", name)
+	assert.Equal("myTestSpan", name)
 
 	durationMilli := mainEventFields["duration_ms"]
 	durationMilliFl, ok := durationMilli.(float64)
@@ -224,7 +255,7 @@ func TestHoneycombOutputWithMessageEvent(t *testing.T) {
 	assert.Equal(expectedSpanID, spanID)
 
 	name := messageEventFields["name"]
-	assert.Equal("honeycomb/test/myTestSpan", name)
+	assert.Equal("myTestSpan", name)
 
 	durationMilli := messageEventFields["duration_ms"]
 	durationMilliFl, ok := durationMilli.(float64)
@@ -241,7 +272,7 @@ func TestHoneycombOutputWithMessageEvent(t *testing.T) {
 	assert.Equal("handling this...", msgEventName)
 
 	attribute := mainEventFields["request-handled"]
-	assert.Equal("100", attribute)
+	assert.Equal(int64(100), attribute)
 
 	msgEventTraceID := mainEventFields["trace.trace_id"]
 	assert.Equal(honeycombTranslatedTraceID, msgEventTraceID)
@@ -293,3 +324,369 @@ func TestHoneycombOutputWithLinks(t *testing.T) {
 	linkSpanType := linkFields["meta.span_type"]
 	assert.Equal("link", linkSpanType)
 }
+
+func TestHoneycombOutputWithInlineLinks(t *testing.T) {
+	linkTraceID, _ := core.TraceIDFromHex("0102030405060709090a0b0c0d0e0f11")
+	linkSpanID, _ := core.SpanIDFromHex("0102030405060709")
+
+	mockHoneycomb := &libhoney.MockOutput{}
+	assert := assert.New(t)
+
+	exporter, err := NewExporter(Config{
+		ApiKey:      "overridden",
+		Dataset:     "overridden",
+		ServiceName: "opentelemetry-test",
+		InlineLinks: true,
+	})
+	assert.Equal(err, nil)
+
+	libhoney.Init(libhoney.Config{
+		WriteKey: "test",
+		Dataset:  "test",
+		Output:   mockHoneycomb,
+	})
+	exporter.Builder = libhoney.NewBuilder()
+
+	traceID, _ := core.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := core.SpanIDFromHex("0102030405060708")
+	now := time.Now()
+
+	exporter.ExportSpan(context.Background(), &exporttrace.SpanData{
+		SpanContext: core.SpanContext{TraceID: traceID, SpanID: spanID},
+		Name:        "withInlineLink",
+		StartTime:   now,
+		EndTime:     now,
+		SpanKind:    apitrace.SpanKindClient,
+		Links: []apitrace.Link{
+			{SpanContext: core.SpanContext{TraceID: linkTraceID, SpanID: linkSpanID}},
+		},
+	})
+
+	// The sibling link event is still sent, alongside the main span event.
+	assert.Equal(2, len(mockHoneycomb.Events()))
+
+	mainEventFields := mockHoneycomb.Events()[1].Fields()
+	assert.Equal("client", mainEventFields["span.kind"])
+
+	links, ok := mainEventFields["trace.links"].([]SpanLink)
+	assert.Equal(ok, true)
+	assert.Equal(1, len(links))
+	assert.Equal(getHoneycombTraceID(hex.EncodeToString(linkTraceID[:])), links[0].TraceID)
+	assert.Equal("0102030405060709", links[0].SpanID)
+}
+
+func TestHoneycombResourcePrecedence(t *testing.T) {
+	mockHoneycomb := &libhoney.MockOutput{}
+	assert := assert.New(t)
+
+	exporter, err := NewExporter(Config{
+		ApiKey:      "overridden",
+		Dataset:     "overridden",
+		ServiceName: "opentelemetry-test",
+	}, WithResource(resource.New(key.String("host.name", "exporter-host"))))
+	assert.Equal(err, nil)
+
+	libhoney.Init(libhoney.Config{
+		WriteKey: "test",
+		Dataset:  "test",
+		Output:   mockHoneycomb,
+	})
+	exporter.Builder = libhoney.NewBuilder()
+
+	traceID, _ := core.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := core.SpanIDFromHex("0102030405060708")
+	now := time.Now()
+
+	// No per-span Resource: the exporter's default Resource applies,
+	// combining the WithResource attribute with the ServiceName sugar.
+	exporter.ExportSpan(context.Background(), &exporttrace.SpanData{
+		SpanContext: core.SpanContext{TraceID: traceID, SpanID: spanID},
+		Name:        "defaultResource",
+		StartTime:   now,
+		EndTime:     now,
+	})
+
+	// A span carrying its own Resource replaces the exporter's default
+	// entirely, rather than merging with it.
+	exporter.ExportSpan(context.Background(), &exporttrace.SpanData{
+		SpanContext: core.SpanContext{TraceID: traceID, SpanID: spanID},
+		Name:        "overriddenResource",
+		StartTime:   now,
+		EndTime:     now,
+		Resource:    resource.New(key.String("service.name", "span-scoped-service")),
+	})
+
+	assert.Equal(2, len(mockHoneycomb.Events()))
+
+	defaultFields := mockHoneycomb.Events()[0].Fields()
+	assert.Equal("opentelemetry-test", defaultFields["service_name"])
+	assert.Equal("opentelemetry-test", defaultFields["service.name"])
+	assert.Equal("exporter-host", defaultFields["host.name"])
+
+	overriddenFields := mockHoneycomb.Events()[1].Fields()
+	assert.Equal("span-scoped-service", overriddenFields["service_name"])
+	assert.Equal("span-scoped-service", overriddenFields["service.name"])
+	_, hasHost := overriddenFields["host.name"]
+	assert.Equal(false, hasHost)
+}
+
+func TestNewExporterDatasetRoutingByMode(t *testing.T) {
+	assert := assert.New(t)
+
+	// Classic mode requires an explicit Dataset, even with ServiceName
+	// set, since its write key maps directly onto Dataset.
+	_, err := NewExporter(Config{
+		ApiKey:      "key",
+		ServiceName: "my-service",
+		Classic:     true,
+	})
+	assert.Error(err)
+
+	// In classic mode, Dataset routes events exactly as given: it's
+	// never derived from ServiceName.
+	classic, err := NewExporter(Config{
+		ApiKey:      "key",
+		Dataset:     "my-service",
+		ServiceName: "my-service",
+		Classic:     true,
+	})
+	assert.NoError(err)
+	assert.Equal("my-service", classic.Builder.Dataset)
+
+	// Outside classic mode, Dataset isn't required, and ServiceName
+	// never stands in for it: every service routes to the same
+	// environment-scoped dataset regardless of ServiceName, since
+	// service.name is only a field there, not a routing key.
+	environment, err := NewExporter(Config{
+		ApiKey:      "key",
+		ServiceName: "my-service",
+	})
+	assert.NoError(err)
+	assert.Equal(defaultEnvironmentDataset, environment.Builder.Dataset)
+
+	otherService, err := NewExporter(Config{
+		ApiKey:      "key",
+		ServiceName: "a-different-service",
+	})
+	assert.NoError(err)
+	assert.Equal(environment.Builder.Dataset, otherService.Builder.Dataset)
+}
+
+func TestNewBatchExporterAppliesSampler(t *testing.T) {
+	mockHoneycomb := &libhoney.MockOutput{}
+	assert := assert.New(t)
+
+	keptTraceIDHex := "0102030405060708090a0b0c0d0e0f10"
+	droppedTraceIDHex := "1102030405060708090a0b0c0d0e0f11"
+	keptTraceID, _ := core.TraceIDFromHex(keptTraceIDHex)
+	droppedTraceID, _ := core.TraceIDFromHex(droppedTraceIDHex)
+	spanID, _ := core.SpanIDFromHex("0102030405060708")
+
+	exporter, err := NewBatchExporter(Config{
+		ApiKey:  "overridden",
+		Dataset: "overridden",
+	}, BatchConfig{
+		Sampler: func(traceID string) (bool, uint) {
+			if traceID == droppedTraceIDHex {
+				return false, 0
+			}
+			return true, 4
+		},
+	})
+	assert.Equal(err, nil)
+
+	libhoney.Init(libhoney.Config{
+		WriteKey: "test",
+		Dataset:  "test",
+		Output:   mockHoneycomb,
+	})
+	exporter.Builder = libhoney.NewBuilder()
+
+	now := time.Now()
+	exporter.ExportSpans(context.Background(), []*exporttrace.SpanData{
+		{
+			SpanContext: core.SpanContext{TraceID: keptTraceID, SpanID: spanID},
+			Name:        "kept",
+			StartTime:   now,
+			EndTime:     now,
+		},
+		{
+			SpanContext: core.SpanContext{TraceID: droppedTraceID, SpanID: spanID},
+			Name:        "dropped",
+			StartTime:   now,
+			EndTime:     now,
+		},
+	})
+
+	assert.Equal(1, len(mockHoneycomb.Events()))
+	kept := mockHoneycomb.Events()[0]
+	assert.Equal("kept", kept.Fields()["name"])
+	assert.Equal(uint(4), kept.SampleRate)
+}
+
+func TestNewBatchExporterFlushesOnShutdown(t *testing.T) {
+	mockHoneycomb := &libhoney.MockOutput{}
+	assert := assert.New(t)
+
+	exporter, err := NewBatchExporter(Config{
+		ApiKey:      "overridden",
+		Dataset:     "overridden",
+		ServiceName: "opentelemetry-test",
+	}, BatchConfig{
+		MaxExportBatchSize: 10,
+		ScheduledDelay:     time.Hour,
+	})
+	assert.Equal(err, nil)
+
+	libhoney.Init(libhoney.Config{
+		WriteKey: "test",
+		Dataset:  "test",
+		Output:   mockHoneycomb,
+	})
+	exporter.Builder = libhoney.NewBuilder()
+
+	bsp, err := sdktrace.NewBatchSpanProcessor(exporter, exporter.BatchSpanProcessorOptions()...)
+	assert.Equal(err, nil)
+
+	traceID, _ := core.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := core.SpanIDFromHex("0102030405060708")
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		bsp.OnEnd(&exporttrace.SpanData{
+			SpanContext: core.SpanContext{TraceID: traceID, SpanID: spanID, TraceFlags: core.TraceFlagsSampled},
+			Name:        "batched",
+			StartTime:   now,
+			EndTime:     now,
+		})
+	}
+
+	assert.Equal(0, len(mockHoneycomb.Events()))
+
+	bsp.Shutdown()
+
+	assert.Equal(3, len(mockHoneycomb.Events()))
+}
+
+func TestRedactAttributes(t *testing.T) {
+	redact := RedactAttributes(regexp.MustCompile(`(?i)ssn|password`), "REDACTED")
+
+	tests := []struct {
+		name      string
+		key       string
+		value     interface{}
+		wantValue interface{}
+	}{
+		{"matching key", "user.ssn", "123-45-6789", "REDACTED"},
+		{"case-insensitive match", "user.Password", "hunter2", "REDACTED"},
+		{"non-matching key", "user.name", "alice", "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotKey, gotValue, drop := redact(tt.key, tt.value)
+			assert.New(t).Equal(tt.key, gotKey)
+			assert.New(t).Equal(tt.wantValue, gotValue)
+			assert.New(t).Equal(false, drop)
+		})
+	}
+}
+
+func TestRewriteAttributePrefix(t *testing.T) {
+	rewrite := RewriteAttributePrefix("ex.com/", "app.")
+
+	tests := []struct {
+		name    string
+		key     string
+		wantKey string
+	}{
+		{"matching prefix", "ex.com/string", "app.string"},
+		{"non-matching prefix", "other.key", "other.key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotKey, gotValue, drop := rewrite(tt.key, "value")
+			assert.New(t).Equal(tt.wantKey, gotKey)
+			assert.New(t).Equal("value", gotValue)
+			assert.New(t).Equal(false, drop)
+		})
+	}
+}
+
+func TestDropOversizedAttributes(t *testing.T) {
+	dropOversized := DropOversizedAttributes(5)
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		wantDrop bool
+	}{
+		{"under limit", "abc", false},
+		{"at limit", "abcde", false},
+		{"over limit", "abcdef", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, drop := dropOversized("key", tt.value)
+			assert.New(t).Equal(tt.wantDrop, drop)
+		})
+	}
+}
+
+func TestHoneycombOutputAppliesAttributeProcessors(t *testing.T) {
+	linkTraceID, _ := core.TraceIDFromHex("0102030405060709090a0b0c0d0e0f11")
+	linkSpanID, _ := core.SpanIDFromHex("0102030405060709")
+
+	mockHoneycomb := &libhoney.MockOutput{}
+	assert := assert.New(t)
+
+	exporter, err := NewExporter(Config{
+		ApiKey:      "overridden",
+		Dataset:     "overridden",
+		ServiceName: "opentelemetry-test",
+		AttributeProcessors: []AttributeProcessor{
+			RedactAttributes(regexp.MustCompile("secret"), "REDACTED"),
+			RewriteAttributePrefix("ex.com/", "app."),
+		},
+	})
+	assert.Equal(err, nil)
+
+	libhoney.Init(libhoney.Config{
+		WriteKey: "test",
+		Dataset:  "test",
+		Output:   mockHoneycomb,
+	})
+	exporter.Builder = libhoney.NewBuilder()
+
+	traceID, _ := core.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := core.SpanIDFromHex("0102030405060708")
+	now := time.Now()
+
+	exporter.ExportSpan(context.Background(), &exporttrace.SpanData{
+		SpanContext: core.SpanContext{TraceID: traceID, SpanID: spanID},
+		Name:        "myTestSpan",
+		StartTime:   now,
+		EndTime:     now,
+		Attributes: []core.KeyValue{
+			key.String("ex.com/secret", "hunter2"),
+		},
+		MessageEvents: []exporttrace.Event{
+			{Name: "handling this...", Attributes: []core.KeyValue{key.String("ex.com/secret", "hunter2")}},
+		},
+		Links: []apitrace.Link{
+			{
+				SpanContext: core.SpanContext{TraceID: linkTraceID, SpanID: linkSpanID},
+				Attributes:  []core.KeyValue{key.String("ex.com/secret", "hunter2")},
+			},
+		},
+	})
+
+	assert.Equal(3, len(mockHoneycomb.Events()))
+
+	linkFields := mockHoneycomb.Events()[0].Fields()
+	assert.Equal("REDACTED", linkFields["app.secret"])
+
+	messageEventFields := mockHoneycomb.Events()[1].Fields()
+	assert.Equal("REDACTED", messageEventFields["app.secret"])
+
+	mainEventFields := mockHoneycomb.Events()[2].Fields()
+	assert.Equal("REDACTED", mainEventFields["app.secret"])
+}
@@ -0,0 +1,69 @@
+package honeycomb
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	apitrace "go.opentelemetry.io/otel/api/trace"
+)
+
+// honeycombTraceHeader is the header Honeycomb's own Beeline libraries use
+// to propagate trace context when a request doesn't carry a W3C
+// traceparent header.
+const honeycombTraceHeader = "X-Honeycomb-Trace"
+
+// LogFields returns the Honeycomb-formatted trace and span IDs for the
+// span active in ctx, shaped for attaching directly to an application log
+// line so it joins cleanly with the corresponding trace in the Honeycomb
+// UI. The IDs are in the same form honeycombSpan stamps onto exported
+// events. It returns an empty map if ctx carries no active span.
+func LogFields(ctx context.Context) map[string]interface{} {
+	sc := apitrace.SpanFromContext(ctx).SpanContext()
+	if !sc.IsValid() {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"trace.trace_id": getHoneycombTraceID(sc.TraceIDString()),
+		"trace.span_id":  sc.SpanIDString(),
+	}
+}
+
+// TraceContextFromRequest returns the Honeycomb-formatted trace and span
+// IDs carried by req, in the same form honeycombSpan stamps onto exported
+// events. It first looks for a W3C "traceparent" header, then falls back
+// to Honeycomb's own "X-Honeycomb-Trace" header. It returns empty strings
+// if req carries neither.
+func TraceContextFromRequest(req *http.Request) (traceID, spanID string) {
+	ctx := apitrace.TraceContext{}.Extract(context.Background(), req.Header)
+	if sc := apitrace.RemoteSpanContextFromContext(ctx); sc.IsValid() {
+		return getHoneycombTraceID(sc.TraceIDString()), sc.SpanIDString()
+	}
+	return traceContextFromHoneycombHeader(req.Header.Get(honeycombTraceHeader))
+}
+
+// traceContextFromHoneycombHeader parses the trace and span (parent) IDs
+// out of a Honeycomb "X-Honeycomb-Trace" header, in the format Honeycomb's
+// Beeline libraries emit: "<version>;trace_id=...,parent_id=...[,...]".
+func traceContextFromHoneycombHeader(header string) (traceID, spanID string) {
+	_, fields, found := strings.Cut(header, ";")
+	if !found {
+		return "", ""
+	}
+	for _, field := range strings.Split(fields, ",") {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "trace_id":
+			traceID = value
+		case "parent_id":
+			spanID = value
+		}
+	}
+	if len(traceID) == 32 {
+		traceID = getHoneycombTraceID(traceID)
+	}
+	return traceID, spanID
+}
@@ -0,0 +1,66 @@
+package honeycomb
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	libhoney "github.com/honeycombio/libhoney-go"
+)
+
+func TestLogFields(t *testing.T) {
+	assert := assert.New(t)
+
+	mockHoneycomb := &libhoney.MockOutput{}
+	tr, err := setUpTestExporter(mockHoneycomb)
+	assert.Equal(err, nil)
+
+	ctx, span := tr.Start(context.TODO(), "myTestSpan")
+	defer span.End()
+
+	fields := LogFields(ctx)
+	expectedTraceID := getHoneycombTraceID(span.SpanContext().TraceIDString())
+	assert.Equal(expectedTraceID, fields["trace.trace_id"])
+	assert.Equal(span.SpanContext().SpanIDString(), fields["trace.span_id"])
+}
+
+func TestLogFieldsNoActiveSpan(t *testing.T) {
+	assert := assert.New(t)
+
+	fields := LogFields(context.Background())
+	assert.Equal(0, len(fields))
+}
+
+func TestTraceContextFromRequestTraceparent(t *testing.T) {
+	assert := assert.New(t)
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Traceparent", "00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01")
+
+	traceID, spanID := TraceContextFromRequest(req)
+	assert.Equal("01020304-0506-0708-090a-0b0c0d0e0f10", traceID)
+	assert.Equal("0102030405060708", spanID)
+}
+
+func TestTraceContextFromRequestHoneycombHeader(t *testing.T) {
+	assert := assert.New(t)
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set(honeycombTraceHeader, "1;trace_id=0102030405060708090a0b0c0d0e0f10,parent_id=0102030405060708")
+
+	traceID, spanID := TraceContextFromRequest(req)
+	assert.Equal("01020304-0506-0708-090a-0b0c0d0e0f10", traceID)
+	assert.Equal("0102030405060708", spanID)
+}
+
+func TestTraceContextFromRequestNoHeaders(t *testing.T) {
+	assert := assert.New(t)
+
+	req, _ := http.NewRequest("GET", "/", nil)
+
+	traceID, spanID := TraceContextFromRequest(req)
+	assert.Equal("", traceID)
+	assert.Equal("", spanID)
+}
@@ -0,0 +1,120 @@
+package honeycomb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// TeeExporter is a SpanSyncer and SpanBatcher that forwards every span (or
+// batch of spans) it receives to each of a fixed list of destination
+// exporters concurrently, so the same spans can be routed to Honeycomb
+// and to one or more other OpenTelemetry span exporters (for example,
+// stdout, OTLP, Jaeger, or Zipkin) at the same time. This supports
+// running Honeycomb alongside a second backend while evaluating it as a
+// migration target.
+//
+// Destinations run in their own goroutines, so a destination that blocks
+// or panics while handling a span or batch does not delay or prevent the
+// remaining destinations from receiving it: panics are recovered, and
+// every destination's panic from a single call is aggregated into one
+// error (via errors.Join) and reported to OnError, if set.
+type TeeExporter struct {
+	destinations []exporttrace.SpanSyncer
+
+	// OnError, if non-nil, is called synchronously, after every
+	// destination has had a chance to receive the span or batch, with the
+	// aggregated error from any destinations that panicked. A nil OnError
+	// silently discards that error; destinations that didn't panic are
+	// unaffected either way.
+	OnError func(error)
+}
+
+// Tee returns a TeeExporter that fans every span out to each of
+// destinations concurrently; the order in which the destinations receive
+// a span is not defined.
+func Tee(destinations ...exporttrace.SpanSyncer) *TeeExporter {
+	return &TeeExporter{destinations: destinations}
+}
+
+// ExportSpan implements exporttrace.SpanSyncer by forwarding data to every
+// destination concurrently, so a slow or blocking destination doesn't
+// delay the rest.
+func (t *TeeExporter) ExportSpan(ctx context.Context, data *exporttrace.SpanData) {
+	errs := make([]error, len(t.destinations))
+	var wg sync.WaitGroup
+	for i, d := range t.destinations {
+		wg.Add(1)
+		go func(i int, d exporttrace.SpanSyncer) {
+			defer wg.Done()
+			errs[i] = exportSpan(ctx, d, data)
+		}(i, d)
+	}
+	wg.Wait()
+	t.reportError(errs)
+}
+
+// ExportSpans implements exporttrace.SpanBatcher by forwarding spans to
+// every destination concurrently, calling its ExportSpans method
+// directly if it also implements exporttrace.SpanBatcher, and otherwise
+// falling back to one ExportSpan call per span. Running destinations
+// concurrently keeps a slow or blocking one from delaying the rest.
+func (t *TeeExporter) ExportSpans(ctx context.Context, spans []*exporttrace.SpanData) {
+	errs := make([]error, len(t.destinations))
+	var wg sync.WaitGroup
+	for i, d := range t.destinations {
+		wg.Add(1)
+		go func(i int, d exporttrace.SpanSyncer) {
+			defer wg.Done()
+			errs[i] = exportSpans(ctx, d, spans)
+		}(i, d)
+	}
+	wg.Wait()
+	t.reportError(errs)
+}
+
+// reportError joins errs (which may contain nils for destinations that
+// didn't fail) via errors.Join and reports the result to OnError, if set
+// and at least one destination actually failed.
+func (t *TeeExporter) reportError(errs []error) {
+	if joined := errors.Join(errs...); joined != nil && t.OnError != nil {
+		t.OnError(joined)
+	}
+}
+
+// exportSpan calls d.ExportSpan, recovering and reporting any panic as an
+// error rather than letting it propagate to the caller and the other
+// destinations in the tee.
+func exportSpan(ctx context.Context, d exporttrace.SpanSyncer, data *exporttrace.SpanData) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("honeycomb: tee destination panicked: %v", r)
+		}
+	}()
+	d.ExportSpan(ctx, data)
+	return nil
+}
+
+// exportSpans calls d's ExportSpans method if it implements
+// exporttrace.SpanBatcher, or otherwise calls its ExportSpan method once
+// per span, recovering and reporting any panic as an error rather than
+// letting it propagate to the caller and the other destinations in the
+// tee.
+func exportSpans(ctx context.Context, d exporttrace.SpanSyncer, spans []*exporttrace.SpanData) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("honeycomb: tee destination panicked: %v", r)
+		}
+	}()
+	if batcher, ok := d.(exporttrace.SpanBatcher); ok {
+		batcher.ExportSpans(ctx, spans)
+		return nil
+	}
+	for _, data := range spans {
+		d.ExportSpan(ctx, data)
+	}
+	return nil
+}
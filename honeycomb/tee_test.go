@@ -0,0 +1,156 @@
+package honeycomb
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/api/core"
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// recordingExporter is a SpanSyncer and SpanBatcher that records every
+// span it's given, optionally panicking first. Its methods are safe to
+// call concurrently, since a TeeExporter under test calls every
+// destination's methods from its own goroutine.
+type recordingExporter struct {
+	panicOn string
+
+	mu      sync.Mutex
+	spans   []*exporttrace.SpanData
+	batches [][]*exporttrace.SpanData
+}
+
+func (e *recordingExporter) ExportSpan(_ context.Context, data *exporttrace.SpanData) {
+	if data.Name == e.panicOn {
+		panic("boom")
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, data)
+}
+
+func (e *recordingExporter) ExportSpans(_ context.Context, spans []*exporttrace.SpanData) {
+	for _, data := range spans {
+		if data.Name == e.panicOn {
+			panic("boom")
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.batches = append(e.batches, spans)
+}
+
+func (e *recordingExporter) recordedSpans() []*exporttrace.SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*exporttrace.SpanData(nil), e.spans...)
+}
+
+func TestTeeForwardsToEveryDestination(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b := &recordingExporter{}, &recordingExporter{}
+	tee := Tee(a, b)
+
+	spanID, _ := core.SpanIDFromHex("0102030405060708")
+	data := &exporttrace.SpanData{
+		SpanContext: core.SpanContext{SpanID: spanID},
+		Name:        "/foo",
+	}
+	tee.ExportSpan(context.Background(), data)
+
+	assert.Equal([]*exporttrace.SpanData{data}, a.spans)
+	assert.Equal([]*exporttrace.SpanData{data}, b.spans)
+}
+
+func TestTeeSurvivesPanicInOneDestination(t *testing.T) {
+	assert := assert.New(t)
+
+	failing, ok := &recordingExporter{panicOn: "/boom"}, &recordingExporter{}
+	var reported error
+	tee := Tee(failing, ok)
+	tee.OnError = func(err error) { reported = err }
+
+	data := &exporttrace.SpanData{Name: "/boom"}
+	tee.ExportSpan(context.Background(), data)
+
+	assert.Empty(failing.spans)
+	assert.Equal([]*exporttrace.SpanData{data}, ok.spans)
+	assert.Error(reported)
+}
+
+// blockingExporter is a SpanSyncer that blocks ExportSpan until release
+// is closed, for testing that a slow destination doesn't hold up the
+// rest of a tee.
+type blockingExporter struct {
+	release chan struct{}
+}
+
+func (e *blockingExporter) ExportSpan(_ context.Context, _ *exporttrace.SpanData) {
+	<-e.release
+}
+
+func TestTeeDoesNotSerializeOnSlowDestination(t *testing.T) {
+	assert := assert.New(t)
+
+	slow := &blockingExporter{release: make(chan struct{})}
+	fast := &recordingExporter{}
+	tee := Tee(slow, fast)
+
+	done := make(chan struct{})
+	go func() {
+		tee.ExportSpan(context.Background(), &exporttrace.SpanData{Name: "/foo"})
+		close(done)
+	}()
+
+	// fast should receive its span right away, without waiting on slow,
+	// proving the destinations run concurrently rather than in sequence.
+	assert.Eventually(func() bool { return len(fast.recordedSpans()) == 1 }, time.Second, time.Millisecond)
+
+	select {
+	case <-done:
+		t.Fatal("ExportSpan returned before the slow destination was released")
+	default:
+	}
+
+	close(slow.release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ExportSpan never returned after the slow destination was released")
+	}
+}
+
+func TestTeeExportSpansUsesBatcherWhenAvailable(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b := &recordingExporter{}, &recordingExporter{}
+	tee := Tee(a, b)
+
+	spans := []*exporttrace.SpanData{{Name: "/foo"}, {Name: "/bar"}}
+	tee.ExportSpans(context.Background(), spans)
+
+	assert.Equal([][]*exporttrace.SpanData{spans}, a.batches)
+	assert.Equal([][]*exporttrace.SpanData{spans}, b.batches)
+	assert.Empty(a.spans)
+	assert.Empty(b.spans)
+}
+
+func TestTeeExportSpansSurvivesPanicInOneDestination(t *testing.T) {
+	assert := assert.New(t)
+
+	failing, ok := &recordingExporter{panicOn: "/boom"}, &recordingExporter{}
+	var reported error
+	tee := Tee(failing, ok)
+	tee.OnError = func(err error) { reported = err }
+
+	spans := []*exporttrace.SpanData{{Name: "/boom"}}
+	tee.ExportSpans(context.Background(), spans)
+
+	assert.Empty(failing.batches)
+	assert.Equal([][]*exporttrace.SpanData{spans}, ok.batches)
+	assert.Error(reported)
+}